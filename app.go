@@ -1,12 +1,24 @@
 package wiston
 
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
 // App is the central application container that manages modules,
 // logging, and the lifecycle of gateways. It serves as the primary
 // entry point and coordinator for all application components.
 type App struct {
 	Modules        map[string]*Module
-	Logger         *Logger
+	Logger         Logger
 	GatewayManager *GatewayManager
+
+	mu                sync.Mutex
+	cancel            context.CancelFunc
+	shutdownRequested bool
 }
 
 // AddModule adds a given module to the application's module registry.
@@ -23,24 +35,89 @@ func (a *App) UseGateway(gateway Gateway) {
 
 // Start initializes all modules and starts all registered gateways.
 // This method blocks until all gateways have gracefully stopped.
-// It orchestrates the application startup sequence, ensuring modules are
-// initialized before gateways begin their operations.
+// It is a convenience wrapper around Run using a background context and no
+// signal handling beyond what Run installs by default; most applications
+// should prefer Run so they can be shut down with Shutdown or a signal.
 func (a *App) Start() error {
-	a.Logger.Info("Starting application...")
+	return a.Run(context.Background())
+}
+
+// Run initializes all modules, starts all registered gateways, and installs
+// handlers for SIGINT/SIGTERM. It blocks until ctx is canceled, a
+// termination signal is received, or Shutdown is called, at which point it
+// propagates cancellation to the gateways and waits for them to drain
+// before returning. Per-module OnStart hooks run before gateways start, and
+// OnStop hooks run after they have stopped.
+func (a *App) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
 
-	for name := range a.Modules {
-		a.Logger.Info("Initializing module: " + name)
-		// if len(module.onStartCallbacks) > 0 {
-		// 	module.triggerHook("start")
-		// }
-		a.Logger.Info("Module " + name + " initialized")
+	a.mu.Lock()
+	a.cancel = cancel
+	shutdownAlreadyRequested := a.shutdownRequested
+	a.shutdownRequested = false
+	a.mu.Unlock()
+
+	// A Shutdown call that raced ahead of this assignment left its request
+	// behind instead of canceling a cancel that didn't exist yet; honor it
+	// now instead of silently losing it.
+	if shutdownAlreadyRequested {
+		cancel()
 	}
 
-	// Start all gateways
-	wg := a.GatewayManager.StartAll()
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigCh)
+
+	go func() {
+		select {
+		case <-sigCh:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	a.Logger.Info("Starting application...")
+
+	for name, module := range a.Modules {
+		moduleLogger := a.Logger.With(String("module", name))
+		moduleLogger.Info("Initializing module")
+		if err := module.triggerStart(); err != nil {
+			moduleLogger.Error("Module failed to start", Err(err))
+			return err
+		}
+		moduleLogger.Info("Module initialized")
+	}
 
-	// Wait until all gateways exit
+	// Start all gateways and wait until they exit, which happens once ctx
+	// is canceled and they have drained.
+	wg := a.GatewayManager.StartAll(ctx)
 	wg.Wait()
+
+	for name, module := range a.Modules {
+		if err := module.triggerStop(); err != nil {
+			a.Logger.With(String("module", name)).Error("Module failed to stop", Err(err))
+		}
+	}
+
+	return nil
+}
+
+// Shutdown triggers a coordinated shutdown of an application running via
+// Run, canceling its context so gateways are stopped and drained. It is
+// safe to call multiple times, from any goroutine, and even before Run has
+// been called (or before it has finished installing its cancel func), in
+// which case the request is recorded and honored as soon as Run catches
+// up. ctx is accepted for symmetry with Run and future use but shutdown
+// itself is not currently bounded by it.
+func (a *App) Shutdown(ctx context.Context) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.cancel != nil {
+		a.cancel()
+	} else {
+		a.shutdownRequested = true
+	}
 	return nil
 }
 
@@ -50,7 +127,7 @@ func (a *App) Start() error {
 func NewApp() *App {
 	app := &App{
 		Modules: make(map[string]*Module),
-		Logger:  &Logger{},
+		Logger:  NewLogger("stdout"),
 	}
 
 	app.GatewayManager = &GatewayManager{
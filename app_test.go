@@ -0,0 +1,87 @@
+package wiston
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeGateway is a minimal Gateway used to drive App.Run/Shutdown without a
+// real network listener: Start blocks until Stop is called, just like a
+// real gateway blocks until its context is canceled and it has drained.
+type fakeGateway struct {
+	gt      GatewayType
+	stopped chan struct{}
+}
+
+func newFakeGateway(gt GatewayType) *fakeGateway {
+	return &fakeGateway{gt: gt, stopped: make(chan struct{})}
+}
+
+func (g *fakeGateway) GetType() GatewayType  { return g.gt }
+func (g *fakeGateway) SetApp(app *App) error { return nil }
+func (g *fakeGateway) Start() error {
+	<-g.stopped
+	return nil
+}
+func (g *fakeGateway) Stop(ctx context.Context) error {
+	close(g.stopped)
+	return nil
+}
+
+func newTestAppWithGateway() (*App, *fakeGateway) {
+	app := NewApp()
+	gw := newFakeGateway(HTTP_GATEWAY)
+	app.UseGateway(gw)
+	return app, gw
+}
+
+// TestAppShutdownBeforeRunStillStopsRun exercises Shutdown racing ahead of
+// Run: called before Run has had any chance to assign a.cancel, the
+// request must be honored once Run catches up instead of being silently
+// swallowed.
+func TestAppShutdownBeforeRunStillStopsRun(t *testing.T) {
+	app, _ := newTestAppWithGateway()
+
+	if err := app.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- app.Run(context.Background())
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Run returned error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run did not return even though Shutdown was requested before it started")
+	}
+}
+
+// TestAppConcurrentRunAndShutdownAlwaysStopsRun races Shutdown against the
+// start of Run repeatedly, to catch the case where Shutdown lands in the
+// narrow window before a.cancel has been assigned.
+func TestAppConcurrentRunAndShutdownAlwaysStopsRun(t *testing.T) {
+	for i := 0; i < 50; i++ {
+		app, _ := newTestAppWithGateway()
+
+		done := make(chan error, 1)
+		go func() {
+			done <- app.Run(context.Background())
+		}()
+		go app.Shutdown(context.Background())
+
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("Run returned error: %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Run did not return; Shutdown likely raced a.cancel's assignment")
+		}
+	}
+}
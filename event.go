@@ -3,15 +3,12 @@ package wiston
 
 import (
 	"errors"
-	"log"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
 )
 
-// EventListener defines a map of subscription IDs to their corresponding callback functions.
-type EventListener map[int64]func(data any)
-
 // job represents a unit of work to be processed by a worker.
 // It contains the event name and its associated data.
 type job struct {
@@ -32,17 +29,61 @@ const (
 	TimeoutIfFull
 )
 
+// RetryPolicy controls how a failing subscriber callback is retried before
+// it is handed to the bus's dead-letter subscriber.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of times the callback is invoked,
+	// including the first attempt. Values less than 1 are treated as 1.
+	MaxAttempts int
+	// Backoff is the delay between retry attempts.
+	Backoff time.Duration
+}
+
+// SubscribeOptions controls how events are delivered to a single
+// subscriber registered via SubscribeWithOptions or SubscribeWithRetry.
+type SubscribeOptions struct {
+	// Sync delivers events to the callback on the dispatching goroutine
+	// instead of the default one-goroutine-per-callback fan-out.
+	Sync bool
+
+	// QueueSize, when greater than zero, gives this subscriber its own
+	// bounded channel and dedicated goroutine instead of sharing the bus's
+	// worker pool. QueueMode controls what happens when that channel is
+	// full, and QueueTimeout bounds TimeoutIfFull.
+	QueueSize    int
+	QueueMode    PublishMode
+	QueueTimeout time.Duration
+
+	// Retry, when set, is applied when the subscriber's callback returns an
+	// error. Once retries are exhausted, the event is handed to the bus's
+	// dead-letter subscriber registered via OnDropped.
+	Retry *RetryPolicy
+}
+
+// subscription holds a single registered callback along with its delivery
+// configuration and, for subscribers with their own queue, the channel and
+// goroutine serving it.
+type subscription struct {
+	id   int64
+	name string
+	cb   func(any) error
+	opts SubscribeOptions
+	ch   chan any
+	quit chan struct{}
+}
+
 // EventBus provides a thread-safe, asynchronous event bus system.
 // It allows for subscribing to events, publishing events, and unsubscribing.
 // Events are processed concurrently by a pool of workers.
 type EventBus struct {
-	mu      *sync.RWMutex
-	events  map[string]EventListener
-	queue   chan job
-	workers int
-	wg      *sync.WaitGroup
-	quit    chan struct{}
-	counter int64
+	mu        *sync.RWMutex
+	events    map[string]map[int64]*subscription
+	queue     chan job
+	workers   int
+	wg        *sync.WaitGroup
+	quit      chan struct{}
+	counter   int64
+	onDropped atomic.Pointer[func(name string, data any, reason error)]
 }
 
 // NewEventBus creates and initializes a new EventBus with a specified number
@@ -50,7 +91,7 @@ type EventBus struct {
 func NewEventBus(workers int, queueSize int) *EventBus {
 	bus := &EventBus{
 		mu:      &sync.RWMutex{},
-		events:  make(map[string]EventListener),
+		events:  make(map[string]map[int64]*subscription),
 		queue:   make(chan job, queueSize),
 		workers: workers,
 		wg:      &sync.WaitGroup{},
@@ -78,40 +119,186 @@ func (b *EventBus) startWorkers() {
 	}
 }
 
-// dispatch finds all listeners for a given job's event and invokes them concurrently.
-// It recovers from panics within listeners to prevent crashing the worker.
+// dispatch finds all subscriptions for a given job's event and delivers the
+// data to each according to its SubscribeOptions.
 func (b *EventBus) dispatch(j job) {
 	b.mu.RLock()
-	listeners := make([]func(any), 0, len(b.events[j.name]))
-	for _, fn := range b.events[j.name] {
-		listeners = append(listeners, fn)
+	subs := make([]*subscription, 0, len(b.events[j.name]))
+	for _, sub := range b.events[j.name] {
+		subs = append(subs, sub)
 	}
 	b.mu.RUnlock()
 
-	for _, fn := range listeners {
-		go func(fn func(any), data any) {
-			defer func() {
-				if r := recover(); r != nil {
-					log.Println("listener panic:", r)
-				}
-			}()
-			fn(data)
-		}(fn, j.data)
+	for _, sub := range subs {
+		b.deliver(sub, j.data)
+	}
+}
+
+// deliver routes a single event to a subscription according to its
+// SubscribeOptions: synchronously, via its own queue, or via a dedicated
+// goroutine (the default).
+func (b *EventBus) deliver(sub *subscription, data any) {
+	switch {
+	case sub.opts.Sync:
+		b.invoke(sub, data)
+	case sub.ch != nil:
+		b.enqueue(sub, data)
+	default:
+		go b.invoke(sub, data)
 	}
 }
 
+// enqueue places data onto a per-subscriber queue, honoring its QueueMode.
+// Events that cannot be enqueued are handed to the dead-letter subscriber.
+func (b *EventBus) enqueue(sub *subscription, data any) {
+	switch sub.opts.QueueMode {
+	case BlockIfFull:
+		select {
+		case sub.ch <- data:
+		case <-sub.quit:
+			b.drop(sub.name, data, errors.New("subscriber unsubscribed"))
+		}
+	case TimeoutIfFull:
+		timer := time.NewTimer(sub.opts.QueueTimeout)
+		defer timer.Stop()
+		select {
+		case sub.ch <- data:
+		case <-timer.C:
+			b.drop(sub.name, data, errors.New("subscriber queue full: publish timeout"))
+		case <-sub.quit:
+			b.drop(sub.name, data, errors.New("subscriber unsubscribed"))
+		}
+	default: // DropIfFull
+		select {
+		case <-sub.quit:
+			b.drop(sub.name, data, errors.New("subscriber unsubscribed"))
+		default:
+			select {
+			case sub.ch <- data:
+			default:
+				b.drop(sub.name, data, errors.New("subscriber queue full: dropped event"))
+			}
+		}
+	}
+}
+
+// startQueue launches the dedicated goroutine that drains a subscriber's
+// own queue, delivering events one at a time in order.
+func (b *EventBus) startQueue(sub *subscription) {
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		for {
+			select {
+			case data := <-sub.ch:
+				b.invoke(sub, data)
+			case <-sub.quit:
+				return
+			}
+		}
+	}()
+}
+
+// invoke calls a subscription's callback, recovering from panics and
+// applying its RetryPolicy on error. If the callback still fails (or keeps
+// panicking) once retries are exhausted, the event is handed to the bus's
+// dead-letter subscriber.
+func (b *EventBus) invoke(sub *subscription, data any) {
+	attempts := 1
+	if sub.opts.Retry != nil && sub.opts.Retry.MaxAttempts > attempts {
+		attempts = sub.opts.Retry.MaxAttempts
+	}
+
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err := b.call(sub, data); err != nil {
+			lastErr = err
+			if attempt < attempts && sub.opts.Retry != nil && sub.opts.Retry.Backoff > 0 {
+				time.Sleep(sub.opts.Retry.Backoff)
+			}
+			continue
+		}
+		return
+	}
+
+	if lastErr != nil {
+		b.drop(sub.name, data, lastErr)
+	}
+}
+
+// call invokes cb once, converting a panic into an error so invoke can
+// apply the same retry and dead-letter handling to panics as to errors.
+func (b *EventBus) call(sub *subscription, data any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("listener panic: %v", r)
+		}
+	}()
+	return sub.cb(data)
+}
+
+// drop hands an event that could not be delivered to the dead-letter
+// subscriber registered via OnDropped, if any.
+func (b *EventBus) drop(name string, data any, reason error) {
+	if fn := b.onDropped.Load(); fn != nil {
+		(*fn)(name, data, reason)
+	}
+}
+
+// OnDropped registers a callback invoked whenever an event is rejected,
+// times out, or its subscriber panics or exhausts its retries, instead of
+// the event silently disappearing. Only one dead-letter subscriber can be
+// registered at a time; calling OnDropped again replaces the previous one.
+func (b *EventBus) OnDropped(fn func(name string, data any, reason error)) {
+	b.onDropped.Store(&fn)
+}
+
 // Subscribe registers a callback function for a given event name.
 // It returns a unique subscription ID that can be used to unsubscribe later.
 func (b *EventBus) Subscribe(name string, callback func(any)) int64 {
+	return b.SubscribeWithOptions(name, callback, SubscribeOptions{})
+}
+
+// SubscribeWithOptions registers a callback for a given event name with
+// explicit delivery semantics (synchronous, a dedicated per-subscriber
+// queue, or the default goroutine-per-event fan-out), as an alternative to
+// Subscribe. It returns a unique subscription ID that can be used to
+// unsubscribe later. Since callback cannot return an error, opts.Retry
+// applies only when it panics; use SubscribeWithRetry for callbacks that
+// report errors.
+func (b *EventBus) SubscribeWithOptions(name string, callback func(any), opts SubscribeOptions) int64 {
+	return b.addSubscription(name, func(data any) error {
+		callback(data)
+		return nil
+	}, opts)
+}
+
+// SubscribeWithRetry registers a callback that can report failure by
+// returning an error. If opts.Retry is set, a failing callback is retried
+// up to MaxAttempts times with Backoff between attempts before the event is
+// handed to the dead-letter subscriber registered via OnDropped. It returns
+// a unique subscription ID that can be used to unsubscribe later.
+func (b *EventBus) SubscribeWithRetry(name string, callback func(any) error, opts SubscribeOptions) int64 {
+	return b.addSubscription(name, callback, opts)
+}
+
+// addSubscription records a subscription and, if it uses its own queue,
+// starts the goroutine that serves it.
+func (b *EventBus) addSubscription(name string, cb func(any) error, opts SubscribeOptions) int64 {
+	id := atomic.AddInt64(&b.counter, 1)
+	sub := &subscription{id: id, name: name, cb: cb, opts: opts}
+	if opts.QueueSize > 0 {
+		sub.ch = make(chan any, opts.QueueSize)
+		sub.quit = make(chan struct{})
+		b.startQueue(sub)
+	}
+
 	b.mu.Lock()
 	defer b.mu.Unlock()
-
 	if b.events[name] == nil {
-		b.events[name] = make(EventListener)
+		b.events[name] = make(map[int64]*subscription)
 	}
-
-	id := atomic.AddInt64(&b.counter, 1)
-	b.events[name][id] = callback
+	b.events[name][id] = sub
 	return id
 }
 
@@ -119,22 +306,28 @@ func (b *EventBus) Subscribe(name string, callback func(any)) int64 {
 func (b *EventBus) Unsubscribe(name string, id int64) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	if listeners, ok := b.events[name]; ok {
-		delete(listeners, id)
+	if subs, ok := b.events[name]; ok {
+		if sub, ok := subs[id]; ok && sub.quit != nil {
+			close(sub.quit)
+		}
+		delete(subs, id)
 	}
 }
 
 // Publish sends an event with the given name and data to the event bus.
 // The behavior when the queue is full is determined by the PublishMode.
-// An error is returned if the event is dropped or times out.
+// An error is returned if the event is dropped or times out; in that case
+// the event is also handed to the dead-letter subscriber registered via
+// OnDropped.
 func (b *EventBus) Publish(name string, data any, mode PublishMode, timeout ...time.Duration) error {
 	job := job{name, data}
+	var err error
 	switch mode {
 	case DropIfFull:
 		select {
 		case b.queue <- job:
 		default:
-			return errors.New("queue full: dropped event")
+			err = errors.New("queue full: dropped event")
 		}
 	case BlockIfFull:
 		b.queue <- job
@@ -145,9 +338,14 @@ func (b *EventBus) Publish(name string, data any, mode PublishMode, timeout ...t
 		select {
 		case b.queue <- job:
 		case <-time.After(timeout[0]):
-			return errors.New("queue full: publish timeout")
+			err = errors.New("queue full: publish timeout")
 		}
 	}
+
+	if err != nil {
+		b.drop(name, data, err)
+		return err
+	}
 	return nil
 }
 
@@ -155,5 +353,16 @@ func (b *EventBus) Publish(name string, data any, mode PublishMode, timeout ...t
 // It stops all worker goroutines and waits for them to finish their current tasks.
 func (b *EventBus) Close() {
 	close(b.quit)
+
+	b.mu.Lock()
+	for _, subs := range b.events {
+		for _, sub := range subs {
+			if sub.quit != nil {
+				close(sub.quit)
+			}
+		}
+	}
+	b.mu.Unlock()
+
 	b.wg.Wait()
 }
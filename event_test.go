@@ -0,0 +1,214 @@
+package wiston
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestPublishDropIfFullInvokesOnDropped(t *testing.T) {
+	bus := NewEventBus(0, 0)
+	defer bus.Close()
+
+	dropped := make(chan error, 1)
+	bus.OnDropped(func(name string, data any, reason error) {
+		dropped <- reason
+	})
+
+	err := bus.Publish("ev", "x", DropIfFull)
+	if err == nil {
+		t.Fatal("expected Publish to return an error when the queue is full")
+	}
+
+	select {
+	case reason := <-dropped:
+		if reason != err {
+			t.Fatalf("drop reason = %v, want %v", reason, err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected OnDropped to be invoked")
+	}
+}
+
+func TestSubscribeWithOptionsDropIfFullDropsWhenSubscriberQueueIsFull(t *testing.T) {
+	bus := NewEventBus(1, 8)
+	defer bus.Close()
+
+	block := make(chan struct{})
+	started := make(chan struct{}, 1)
+	bus.SubscribeWithOptions("ev", func(any) {
+		started <- struct{}{}
+		<-block
+	}, SubscribeOptions{QueueSize: 1, QueueMode: DropIfFull})
+
+	dropped := make(chan error, 1)
+	bus.OnDropped(func(name string, data any, reason error) {
+		dropped <- reason
+	})
+
+	bus.Publish("ev", 1, DropIfFull)
+	<-started // first event is now being processed, freeing the queue slot
+
+	bus.Publish("ev", 2, DropIfFull) // fills the one-slot queue
+	bus.Publish("ev", 3, DropIfFull) // queue full and not draining, should be dropped
+
+	// Wait for the drop itself, rather than closing block first: Publish
+	// only enqueues onto the bus's own async queue, so its return gives no
+	// guarantee the worker has reached event 3 yet. Closing block before
+	// that drop is observed races the callback unblocking (and draining
+	// event 2) against the drop of event 3.
+	select {
+	case reason := <-dropped:
+		if reason == nil {
+			t.Fatal("expected a non-nil drop reason")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected event 3 to be dropped while the queue was still full")
+	}
+
+	close(block)
+}
+
+func TestSubscribeWithOptionsTimeoutIfFullDropsAfterTimeout(t *testing.T) {
+	bus := NewEventBus(1, 8)
+	defer bus.Close()
+
+	block := make(chan struct{})
+	started := make(chan struct{}, 1)
+	bus.SubscribeWithOptions("ev", func(any) {
+		started <- struct{}{}
+		<-block
+	}, SubscribeOptions{QueueSize: 1, QueueMode: TimeoutIfFull, QueueTimeout: 20 * time.Millisecond})
+
+	dropped := make(chan error, 1)
+	bus.OnDropped(func(name string, data any, reason error) {
+		dropped <- reason
+	})
+
+	bus.Publish("ev", 1, DropIfFull)
+	<-started
+
+	bus.Publish("ev", 2, DropIfFull) // fills the one-slot queue
+	bus.Publish("ev", 3, DropIfFull) // queue full and not draining, should time out and drop
+
+	select {
+	case reason := <-dropped:
+		if reason == nil {
+			t.Fatal("expected a non-nil drop reason")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected event 3 to be dropped once the queue timeout elapsed")
+	}
+
+	close(block)
+}
+
+func TestSubscribeWithOptionsBlockIfFullDeliversEveryEvent(t *testing.T) {
+	bus := NewEventBus(1, 8)
+	defer bus.Close()
+
+	block := make(chan struct{})
+	started := make(chan struct{}, 1)
+	done := make(chan struct{})
+	var delivered int32
+
+	bus.SubscribeWithOptions("ev", func(data any) {
+		if data.(int) == 1 {
+			started <- struct{}{}
+			<-block
+		}
+		if atomic.AddInt32(&delivered, 1) == 3 {
+			close(done)
+		}
+	}, SubscribeOptions{QueueSize: 1, QueueMode: BlockIfFull})
+
+	var dropped int32
+	bus.OnDropped(func(name string, data any, reason error) {
+		atomic.AddInt32(&dropped, 1)
+	})
+
+	bus.Publish("ev", 1, DropIfFull)
+	<-started
+
+	bus.Publish("ev", 2, DropIfFull)
+	bus.Publish("ev", 3, DropIfFull)
+
+	close(block)
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("not all events were delivered")
+	}
+
+	if got := atomic.LoadInt32(&dropped); got != 0 {
+		t.Fatalf("OnDropped called %d times, want 0 for BlockIfFull", got)
+	}
+}
+
+func TestSubscribeWithRetryRetriesThenDeadLetters(t *testing.T) {
+	bus := NewEventBus(2, 8)
+	defer bus.Close()
+
+	var attempts int32
+	bus.SubscribeWithRetry("ev", func(any) error {
+		atomic.AddInt32(&attempts, 1)
+		return errors.New("boom")
+	}, SubscribeOptions{Retry: &RetryPolicy{MaxAttempts: 3, Backoff: time.Millisecond}})
+
+	dropped := make(chan error, 1)
+	bus.OnDropped(func(name string, data any, reason error) {
+		dropped <- reason
+	})
+
+	if err := bus.Publish("ev", "payload", DropIfFull); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	select {
+	case reason := <-dropped:
+		if reason == nil {
+			t.Fatal("expected a non-nil drop reason")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the dead-letter callback once retries were exhausted")
+	}
+
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Fatalf("attempts = %d, want 3 (MaxAttempts)", got)
+	}
+}
+
+func TestSubscribeWithRetrySucceedsWithinAttemptsSkipsDeadLetter(t *testing.T) {
+	bus := NewEventBus(1, 8)
+	defer bus.Close()
+
+	var attempts int32
+	ok := make(chan struct{})
+	bus.SubscribeWithRetry("ev", func(any) error {
+		if atomic.AddInt32(&attempts, 1) < 2 {
+			return errors.New("transient")
+		}
+		close(ok)
+		return nil
+	}, SubscribeOptions{Retry: &RetryPolicy{MaxAttempts: 3}})
+
+	var dropped int32
+	bus.OnDropped(func(name string, data any, reason error) {
+		atomic.AddInt32(&dropped, 1)
+	})
+
+	bus.Publish("ev", nil, DropIfFull)
+
+	select {
+	case <-ok:
+	case <-time.After(time.Second):
+		t.Fatal("callback never succeeded")
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if got := atomic.LoadInt32(&dropped); got != 0 {
+		t.Fatalf("OnDropped called %d times, want 0", got)
+	}
+}
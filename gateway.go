@@ -1,10 +1,18 @@
 package wiston
 
 import (
+	"context"
 	"net/http"
 	"sync"
+	"time"
 )
 
+// DefaultDrainTimeout is the default lifetime of the context GatewayManager
+// passes to each gateway's Stop once shutdown is triggered. Gateways are not
+// force-closed when it expires: StartAll's WaitGroup still waits for every
+// gateway's Start to return.
+const DefaultDrainTimeout = 10 * time.Second
+
 // GatewayType defines the type of a gateway, such as HTTP or WebSocket.
 type GatewayType int
 
@@ -13,8 +21,36 @@ const (
 	HTTP_GATEWAY GatewayType = iota
 	// WS_GATEWAY represents a WebSocket server gateway.
 	WS_GATEWAY
+	// RPC_GATEWAY represents a JSON-RPC 2.0 gateway, reachable over HTTP
+	// and/or WebSocket.
+	RPC_GATEWAY
+)
+
+// JSON-RPC 2.0 standard error codes, used with RpcContext.Error and the
+// errors an RpcGateway returns for malformed calls.
+const (
+	RpcParseError     = -32700
+	RpcInvalidRequest = -32600
+	RpcMethodNotFound = -32601
+	RpcInvalidParams  = -32602
+	RpcInternalError  = -32603
 )
 
+// gatewayTypeLabel returns the human-readable name used in log fields for
+// a GatewayType.
+func gatewayTypeLabel(gt GatewayType) string {
+	switch gt {
+	case HTTP_GATEWAY:
+		return "HTTP"
+	case WS_GATEWAY:
+		return "WS"
+	case RPC_GATEWAY:
+		return "RPC"
+	default:
+		return "unknown"
+	}
+}
+
 // Gateway is the fundamental interface for network gateways.
 // It defines the basic methods that all gateway types must implement.
 type Gateway interface {
@@ -24,6 +60,10 @@ type Gateway interface {
 	SetApp(app *App) error
 	// Start launches the gateway, making it ready to accept connections.
 	Start() error
+	// Stop gracefully shuts the gateway down, closing listeners and draining
+	// in-flight work. It should return once shutdown is complete or once
+	// ctx is done, whichever comes first.
+	Stop(ctx context.Context) error
 }
 
 // HttpGateway defines the interface for an HTTP gateway, extending the base Gateway.
@@ -78,6 +118,12 @@ type HttpContext interface {
 	Method() string
 	Path() string
 	Protocol() string
+	// Scheme returns the request's scheme, "http" or "https", regardless of
+	// what Protocol (the HTTP version) reports.
+	Scheme() string
+	// Host returns the request's target host (and port, if present), as
+	// promoted from the Host header by the underlying transport.
+	Host() string
 
 	Param(key string) string
 	Query(key string) string
@@ -117,7 +163,7 @@ type HttpHandler func(HttpContext)
 // under a common path prefix and with shared middleware.
 type HttpScope interface {
 	Use(mw ...HttpHandler)
-	SetLogger(logger *Logger)
+	SetLogger(logger Logger)
 	Get(path string, handlers ...HttpHandler)
 	Post(path string, handlers ...HttpHandler)
 	Put(path string, handlers ...HttpHandler)
@@ -151,7 +197,7 @@ type WsHandler func(WsContext)
 // WsNamespace provides an interface for defining a logical grouping
 // of WebSocket event handlers.
 type WsNamespace interface {
-	SetLogger(logger *Logger)
+	SetLogger(logger Logger)
 	On(event string, handlers ...WsHandler)
 }
 
@@ -164,12 +210,57 @@ type WsGateway interface {
 	CreateRoom(name string) error
 	HasRoom(name string) bool
 	CreateNamespace(module *Module, name string) WsNamespace
+	// SetWriteDeadline bounds how long a single outbound frame may take to
+	// write to any client connection. A zero duration disables the
+	// deadline. It applies to Emit, EmitToRoom, and Broadcast alike.
+	SetWriteDeadline(d time.Duration)
+}
+
+// RpcContext defines the interface for the context of a single JSON-RPC
+// call. It mirrors the JSON-RPC 2.0 response shape: exactly one of Result
+// or Error should be called for a request, and neither for a notification
+// (a call with no id), since notifications expect no response.
+type RpcContext interface {
+	// Method returns the name of the method being invoked.
+	Method() string
+	// Params decodes the call's parameters into dst, as with json.Unmarshal.
+	Params(dst any) error
+	// ClientID returns the identifier of the client that issued the call,
+	// when transported over a stateful connection such as WebSocket.
+	ClientID() string
+	// Result sends a successful JSON-RPC response carrying data.
+	Result(data any)
+	// Error sends a JSON-RPC error response with the given code, message,
+	// and optional structured data.
+	Error(code int, message string, data any)
+}
+
+// RpcHandler defines the function signature for handling a JSON-RPC call.
+type RpcHandler func(RpcContext)
+
+// RpcGateway defines the interface for a JSON-RPC 2.0 gateway, extending the
+// base Gateway. It accepts single requests, batch requests, and
+// notifications over HTTP and/or WebSocket, and can issue server-initiated
+// calls to connected WebSocket clients.
+type RpcGateway interface {
+	Gateway
+	// RegisterMethod registers a handler for a JSON-RPC method under the
+	// given module.
+	RegisterMethod(module *Module, name string, handler RpcHandler)
+	// Notify sends a JSON-RPC notification (a call with no id, expecting no
+	// response) to the given client over its WebSocket connection.
+	Notify(client string, method string, params any)
 }
 
 // GatewayManager manages the lifecycle of all registered gateways within an application.
 type GatewayManager struct {
 	App     *App
 	Gateway map[GatewayType]Gateway
+
+	// DrainTimeout bounds how long StartAll waits for a gateway's Stop to
+	// return once shutdown has been triggered. If zero, DefaultDrainTimeout
+	// is used.
+	DrainTimeout time.Duration
 }
 
 // UseGateway registers a new Gateway with the manager and associates it with the app.
@@ -180,34 +271,60 @@ func (g *GatewayManager) UseGateway(gateway Gateway) {
 }
 
 // StartAll launches all registered gateways concurrently.
-// It returns a sync.WaitGroup that callers can use to wait for all gateways to stop.
-func (g *GatewayManager) StartAll() *sync.WaitGroup {
+// It returns a sync.WaitGroup that callers can use to wait for all gateways
+// to stop. When ctx is canceled, StartAll calls Stop on every registered
+// gateway with a context that expires after DrainTimeout.
+func (g *GatewayManager) StartAll(ctx context.Context) *sync.WaitGroup {
 	var wg sync.WaitGroup
-	if g.Gateway[HTTP_GATEWAY] != nil {
-		httpGateway := g.Gateway[HTTP_GATEWAY]
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			g.App.Logger.Info("Starting HTTP Gateway...")
-			if err := httpGateway.Start(); err != nil {
-				g.App.Logger.Error("HTTP Gateway failed: " + err.Error())
-			}
-			g.App.Logger.Info("HTTP Gateway stopped")
-		}()
-	}
 
-	if g.Gateway[WS_GATEWAY] != nil {
-		wsGateway := g.Gateway[WS_GATEWAY]
+	start := func(gt GatewayType) {
+		gateway := g.Gateway[gt]
+		if gateway == nil {
+			return
+		}
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			g.App.Logger.Info("Starting WS Gateway...")
-			if err := wsGateway.Start(); err != nil {
-				g.App.Logger.Error("WS Gateway failed: " + err.Error())
+			gatewayLogger := g.App.Logger.With(String("gateway", gatewayTypeLabel(gt)))
+			gatewayLogger.Info("Starting gateway")
+			if err := gateway.Start(); err != nil {
+				gatewayLogger.Error("Gateway failed", Err(err))
 			}
-			g.App.Logger.Info("WS Gateway stopped")
+			gatewayLogger.Info("Gateway stopped")
 		}()
 	}
 
+	start(HTTP_GATEWAY)
+	start(WS_GATEWAY)
+	start(RPC_GATEWAY)
+
+	go func() {
+		<-ctx.Done()
+		g.stopAll()
+	}()
+
 	return &wg
 }
+
+// stopAll calls Stop on every registered gateway concurrently, each with a
+// context bounded by DrainTimeout, and waits for every Stop to return.
+func (g *GatewayManager) stopAll() {
+	timeout := g.DrainTimeout
+	if timeout <= 0 {
+		timeout = DefaultDrainTimeout
+	}
+
+	var stopWg sync.WaitGroup
+	for gt, gateway := range g.Gateway {
+		stopWg.Add(1)
+		go func(gt GatewayType, gw Gateway) {
+			defer stopWg.Done()
+			stopCtx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			if err := gw.Stop(stopCtx); err != nil {
+				g.App.Logger.With(String("gateway", gatewayTypeLabel(gt))).Error("Gateway stop failed", Err(err))
+			}
+		}(gt, gateway)
+	}
+	stopWg.Wait()
+}
@@ -1,79 +1,376 @@
 package wiston
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
-// Logger provides a simple logging facility that can write to either
-// standard output (stdout) or a specified file.
-type Logger struct {
-	target string
-	file   *os.File
-}
-
-// NewLogger creates and returns a new Logger instance.
-// If the target is "stdout", logs will be printed to the console.
-// Otherwise, the target is treated as a file path, and logs will be
-// appended to that file. The file is created if it doesn't exist.
-// The function will fatal log if the log file cannot be opened.
-func NewLogger(target string) *Logger {
-	l := &Logger{target: target}
-
-	if target != "stdout" {
-		f, err := os.OpenFile(target, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-		if err != nil {
-			log.Fatalf("Can't open log file %s: %v", target, err)
+// Level represents the severity of a log entry. Levels are ordered, with
+// LevelDebug the least severe and LevelError the most.
+type Level int32
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// String returns the level's name, as used by every built-in Sink.
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// FieldType identifies the kind of value a Field carries.
+type FieldType int
+
+const (
+	// StringFieldType holds a string value in Field.Str.
+	StringFieldType FieldType = iota
+	// IntFieldType holds an integer value in Field.Int.
+	IntFieldType
+	// ErrorFieldType holds an error value in Field.Err.
+	ErrorFieldType
+	// AnyFieldType holds an arbitrary value in Field.Value.
+	AnyFieldType
+)
+
+// Field is a typed key/value pair attached to a log entry.
+type Field struct {
+	Key   string
+	Type  FieldType
+	Str   string
+	Int   int64
+	Err   error
+	Value any
+}
+
+// String creates a string Field.
+func String(key, value string) Field {
+	return Field{Key: key, Type: StringFieldType, Str: value}
+}
+
+// Int creates an int Field.
+func Int(key string, value int) Field {
+	return Field{Key: key, Type: IntFieldType, Int: int64(value)}
+}
+
+// Err creates a Field keyed "error" carrying err.
+func Err(err error) Field {
+	return Field{Key: "error", Type: ErrorFieldType, Err: err}
+}
+
+// Any creates a Field carrying an arbitrary value.
+func Any(key string, value any) Field {
+	return Field{Key: key, Type: AnyFieldType, Value: value}
+}
+
+// value returns the Field's payload as a plain any, suitable for a JSON
+// sink or similar structured output.
+func (f Field) value() any {
+	switch f.Type {
+	case StringFieldType:
+		return f.Str
+	case IntFieldType:
+		return f.Int
+	case ErrorFieldType:
+		if f.Err == nil {
+			return nil
+		}
+		return f.Err.Error()
+	default:
+		return f.Value
+	}
+}
+
+// text returns the Field's payload formatted for a human-readable sink.
+func (f Field) text() string {
+	switch f.Type {
+	case StringFieldType:
+		return f.Str
+	case IntFieldType:
+		return strconv.FormatInt(f.Int, 10)
+	case ErrorFieldType:
+		if f.Err == nil {
+			return "<nil>"
 		}
-		l.file = f
+		return f.Err.Error()
+	default:
+		return fmt.Sprintf("%v", f.Value)
+	}
+}
+
+// Entry is a single structured log record passed to a Sink.
+type Entry struct {
+	Time   time.Time
+	Level  Level
+	Msg    string
+	Fields []Field
+}
+
+// Sink receives log entries that have passed the Logger's level filter.
+// Implementations must be safe for concurrent use.
+type Sink interface {
+	Write(entry Entry)
+}
+
+// Logger is wiston's structured, leveled logging facility. With returns a
+// child Logger that attaches fields to every entry it logs, letting
+// per-module or per-request context propagate without repeating it at
+// every call site.
+type Logger interface {
+	Debug(msg string, fields ...Field)
+	Info(msg string, fields ...Field)
+	Warn(msg string, fields ...Field)
+	Error(msg string, fields ...Field)
+	With(fields ...Field) Logger
+	SetLevel(level Level)
+}
+
+// logger is the default Logger implementation. It fans each entry out to
+// every configured Sink. Children created via With share the parent's
+// level and sinks, so SetLevel on any of them adjusts the whole family.
+type logger struct {
+	level  *int32
+	sinks  []Sink
+	fields []Field
+}
+
+// NewLoggerWithSinks creates a Logger at LevelInfo that writes every entry
+// to each of the given sinks.
+func NewLoggerWithSinks(sinks ...Sink) Logger {
+	level := int32(LevelInfo)
+	return &logger{level: &level, sinks: sinks}
+}
+
+// NewLogger creates a Logger over a single sink, chosen by target:
+// "stdout" for human-readable console output, or a file path to append
+// JSON-lines to. It is a thin adapter kept for simple callers; use
+// NewLoggerWithSinks to combine multiple sinks or add rotation via
+// NewRotatingFileSink.
+func NewLogger(target string) Logger {
+	if target == "stdout" {
+		return NewLoggerWithSinks(NewStdoutSink())
+	}
+
+	f, err := os.OpenFile(target, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Fatalf("Can't open log file %s: %v", target, err)
+	}
+	return NewLoggerWithSinks(NewJSONSink(f))
+}
+
+func (l *logger) log(level Level, msg string, fields ...Field) {
+	if Level(atomic.LoadInt32(l.level)) > level {
+		return
+	}
+
+	all := make([]Field, 0, len(l.fields)+len(fields))
+	all = append(all, l.fields...)
+	all = append(all, fields...)
+
+	entry := Entry{Time: time.Now(), Level: level, Msg: msg, Fields: all}
+	for _, sink := range l.sinks {
+		sink.Write(entry)
+	}
+}
+
+// Debug logs a message at LevelDebug.
+func (l *logger) Debug(msg string, fields ...Field) { l.log(LevelDebug, msg, fields...) }
+
+// Info logs a message at LevelInfo.
+func (l *logger) Info(msg string, fields ...Field) { l.log(LevelInfo, msg, fields...) }
+
+// Warn logs a message at LevelWarn.
+func (l *logger) Warn(msg string, fields ...Field) { l.log(LevelWarn, msg, fields...) }
+
+// Error logs a message at LevelError.
+func (l *logger) Error(msg string, fields ...Field) { l.log(LevelError, msg, fields...) }
+
+// With returns a child Logger that attaches fields to every entry it logs,
+// in addition to any fields already attached by its ancestors.
+func (l *logger) With(fields ...Field) Logger {
+	combined := make([]Field, 0, len(l.fields)+len(fields))
+	combined = append(combined, l.fields...)
+	combined = append(combined, fields...)
+	return &logger{level: l.level, sinks: l.sinks, fields: combined}
+}
+
+// SetLevel changes the minimum level logged, affecting this Logger and
+// every relative created from it via With.
+func (l *logger) SetLevel(level Level) {
+	atomic.StoreInt32(l.level, int32(level))
+}
+
+// StdoutSink writes entries to stdout in a human-readable line format.
+type StdoutSink struct{}
+
+// NewStdoutSink creates a StdoutSink.
+func NewStdoutSink() *StdoutSink {
+	return &StdoutSink{}
+}
+
+// Write implements Sink.
+func (s *StdoutSink) Write(entry Entry) {
+	fmt.Print(formatText(entry))
+}
+
+// formatText renders entry as a single human-readable line.
+func formatText(entry Entry) string {
+	line := fmt.Sprintf("%s [%s] %s", entry.Time.Format(time.RFC3339), entry.Level, entry.Msg)
+	for _, f := range entry.Fields {
+		line += fmt.Sprintf(" %s=%s", f.Key, f.text())
+	}
+	return line + "\n"
+}
+
+// JSONSink writes entries as newline-delimited JSON to an io.Writer.
+type JSONSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONSink creates a JSONSink writing to w.
+func NewJSONSink(w io.Writer) *JSONSink {
+	return &JSONSink{w: w}
+}
+
+// Write implements Sink.
+func (s *JSONSink) Write(entry Entry) {
+	record := make(map[string]any, len(entry.Fields)+3)
+	record["time"] = entry.Time.Format(time.RFC3339)
+	record["level"] = entry.Level.String()
+	record["msg"] = entry.Msg
+	for _, f := range entry.Fields {
+		record[f.Key] = f.value()
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return
 	}
+	data = append(data, '\n')
 
-	return l
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write(data); err != nil {
+		log.Printf("failed to write log: %v", err)
+	}
 }
 
-// Info logs a message with the INFO level.
-func (l *Logger) Info(msg string) {
-	l.write("INFO", msg)
+// RotatingFileSink writes newline-delimited JSON entries to a file, moving
+// it aside to a timestamped backup and opening a fresh one whenever it
+// exceeds maxBytes or has been open longer than maxAge. A zero maxBytes or
+// maxAge disables that trigger.
+type RotatingFileSink struct {
+	mu       sync.Mutex
+	path     string
+	maxBytes int64
+	maxAge   time.Duration
+
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	json     *JSONSink
 }
 
-// Success logs a message with the SUCCESS level.
-func (l *Logger) Success(msg string) {
-	l.write("SUCCESS", msg)
+// NewRotatingFileSink opens (creating if necessary) path for appending and
+// returns a Sink that rotates it once it exceeds maxBytes or maxAge.
+func NewRotatingFileSink(path string, maxBytes int64, maxAge time.Duration) (*RotatingFileSink, error) {
+	s := &RotatingFileSink{path: path, maxBytes: maxBytes, maxAge: maxAge}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	s.json = NewJSONSink(rotatingWriter{s})
+	return s, nil
 }
 
-// Warn logs a message with the WARN level.
-func (l *Logger) Warn(msg string) {
-	l.write("WARN", msg)
+func (s *RotatingFileSink) open() error {
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	s.file = f
+	s.size = info.Size()
+	s.openedAt = time.Now()
+	return nil
 }
 
-// Error logs a message with the ERROR level.
-func (l *Logger) Error(msg string) {
-	l.write("ERROR", msg)
+// rotatingWriter adapts RotatingFileSink to io.Writer, since io.Writer's
+// Write([]byte) can't be implemented alongside Sink's Write(Entry) on the
+// same type.
+type rotatingWriter struct{ s *RotatingFileSink }
+
+func (w rotatingWriter) Write(p []byte) (int, error) {
+	return w.s.writeBytes(p)
 }
 
-// write formats and writes a log message to the configured target.
-// Each message is prefixed with a timestamp and the log level.
-func (l *Logger) write(level, msg string) {
-	finalMsg := fmt.Sprintf("%s [%s] %s\n", time.Now().Format(time.RFC3339), level, msg)
-	if l.target == "stdout" {
-		fmt.Print(finalMsg)
-	} else {
-		if l.file != nil {
-			if _, err := l.file.WriteString(finalMsg); err != nil {
-				log.Printf("failed to write log: %v", err)
-			}
+func (s *RotatingFileSink) writeBytes(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.shouldRotate(int64(len(p))) {
+		if err := s.rotate(); err != nil {
+			return 0, err
 		}
+	}
+
+	n, err := s.file.Write(p)
+	s.size += int64(n)
+	return n, err
+}
 
+func (s *RotatingFileSink) shouldRotate(next int64) bool {
+	if s.maxBytes > 0 && s.size+next > s.maxBytes {
+		return true
 	}
+	if s.maxAge > 0 && time.Since(s.openedAt) > s.maxAge {
+		return true
+	}
+	return false
 }
 
-// Close closes the underlying log file if the logger is configured
-// to write to a file. It is a no-op if the target is stdout.
-func (l *Logger) Close() {
-	if l.file != nil {
-		l.file.Close()
-		l.file = nil
+func (s *RotatingFileSink) rotate() error {
+	s.file.Close()
+	backup := fmt.Sprintf("%s.%s", s.path, time.Now().Format("20060102T150405"))
+	if err := os.Rename(s.path, backup); err != nil && !os.IsNotExist(err) {
+		return err
 	}
+	return s.open()
+}
+
+// Write implements Sink, JSON-encoding entry and appending it to the
+// current file, rotating first if needed.
+func (s *RotatingFileSink) Write(entry Entry) {
+	s.json.Write(entry)
+}
+
+// Close closes the underlying file.
+func (s *RotatingFileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
 }
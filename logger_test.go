@@ -0,0 +1,129 @@
+package wiston
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// recordingSink collects every Entry written to it, for assertions.
+type recordingSink struct {
+	entries []Entry
+}
+
+func (s *recordingSink) Write(entry Entry) {
+	s.entries = append(s.entries, entry)
+}
+
+func TestLoggerFiltersBelowItsLevel(t *testing.T) {
+	sink := &recordingSink{}
+	logger := NewLoggerWithSinks(sink)
+	logger.SetLevel(LevelWarn)
+
+	logger.Debug("debug message")
+	logger.Info("info message")
+	logger.Warn("warn message")
+	logger.Error("error message")
+
+	if len(sink.entries) != 2 {
+		t.Fatalf("got %d entries, want 2 (Warn and Error only)", len(sink.entries))
+	}
+	if sink.entries[0].Level != LevelWarn || sink.entries[1].Level != LevelError {
+		t.Fatalf("got levels %v, %v; want Warn, Error", sink.entries[0].Level, sink.entries[1].Level)
+	}
+}
+
+func TestLoggerSetLevelAppliesToChildrenCreatedViaWith(t *testing.T) {
+	sink := &recordingSink{}
+	parent := NewLoggerWithSinks(sink)
+	child := parent.With(String("module", "auth"))
+
+	parent.SetLevel(LevelError)
+	child.Info("should be filtered out")
+	child.Error("should pass through")
+
+	if len(sink.entries) != 1 {
+		t.Fatalf("got %d entries, want 1 (SetLevel on parent should affect child)", len(sink.entries))
+	}
+}
+
+func TestLoggerWithComposesFieldsFromAncestors(t *testing.T) {
+	sink := &recordingSink{}
+	root := NewLoggerWithSinks(sink)
+	withModule := root.With(String("module", "http"))
+	withRequest := withModule.With(String("request_id", "abc123"))
+
+	withRequest.Info("handled request")
+
+	if len(sink.entries) != 1 {
+		t.Fatalf("got %d entries, want 1", len(sink.entries))
+	}
+	fields := sink.entries[0].Fields
+	if len(fields) != 2 {
+		t.Fatalf("got %d fields, want 2 (module and request_id)", len(fields))
+	}
+	if fields[0].Key != "module" || fields[0].Str != "http" {
+		t.Fatalf("fields[0] = %+v, want module=http", fields[0])
+	}
+	if fields[1].Key != "request_id" || fields[1].Str != "abc123" {
+		t.Fatalf("fields[1] = %+v, want request_id=abc123", fields[1])
+	}
+}
+
+func TestRotatingFileSinkRotatesOnSize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	// maxBytes is sized to fit a handful of entries so rotation happens
+	// partway through the loop below, rather than on every single write.
+	const entries = 10
+	const maxBytes = 400
+
+	sink, err := NewRotatingFileSink(path, maxBytes, 0)
+	if err != nil {
+		t.Fatalf("NewRotatingFileSink: %v", err)
+	}
+	defer sink.Close()
+
+	for i := 0; i < entries; i++ {
+		sink.Write(Entry{Time: time.Now(), Level: LevelInfo, Msg: "a reasonably sized log line to force rotation"})
+	}
+
+	backups, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatalf("Glob: %v", err)
+	}
+	if len(backups) == 0 {
+		t.Fatal("expected at least one rotated backup file once maxBytes was exceeded")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat current log file: %v", err)
+	}
+	if info.Size() >= maxBytes {
+		t.Fatalf("current log file size = %d, want it reset below maxBytes after rotation", info.Size())
+	}
+}
+
+func TestRotatingFileSinkRotatesOnAge(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+
+	sink, err := NewRotatingFileSink(path, 0, 20*time.Millisecond)
+	if err != nil {
+		t.Fatalf("NewRotatingFileSink: %v", err)
+	}
+	defer sink.Close()
+
+	sink.Write(Entry{Time: time.Now(), Level: LevelInfo, Msg: "first"})
+	time.Sleep(30 * time.Millisecond)
+	sink.Write(Entry{Time: time.Now(), Level: LevelInfo, Msg: "second, past maxAge"})
+
+	backups, err := filepath.Glob(path + ".*")
+	if err != nil {
+		t.Fatalf("Glob: %v", err)
+	}
+	if len(backups) == 0 {
+		t.Fatal("expected a rotated backup file once maxAge was exceeded")
+	}
+}
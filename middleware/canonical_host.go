@@ -0,0 +1,20 @@
+package middleware
+
+import "github.com/smtdfc/wiston"
+
+// CanonicalHost returns middleware that redirects requests not addressed
+// to host over scheme ("http" or "https") to their canonical
+// scheme://host equivalent, using the given redirect status code
+// (typically http.StatusMovedPermanently).
+func CanonicalHost(scheme, host string, code int) wiston.HttpHandler {
+	return func(c wiston.HttpContext) {
+		if c.Scheme() == scheme && c.Host() == host {
+			c.Next()
+			return
+		}
+
+		c.SetHeader("Location", scheme+"://"+host+c.Path())
+		c.Status(code)
+		c.Abort()
+	}
+}
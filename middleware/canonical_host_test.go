@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/smtdfc/wiston"
+)
+
+func TestCanonicalHostRedirectsOnMismatch(t *testing.T) {
+	c := newFakeHttpContext("")
+	c.scheme = "http"
+	c.host = "example.com"
+
+	nextCalled := false
+	handler := CanonicalHost("https", "www.example.com", http.StatusMovedPermanently)
+	c.runChain(handler, func(wiston.HttpContext) { nextCalled = true })
+
+	if nextCalled {
+		t.Fatal("a mismatched host should not reach the next handler")
+	}
+	if !c.IsAborted() {
+		t.Fatal("expected the redirect to abort the chain")
+	}
+	if c.statusCode != http.StatusMovedPermanently {
+		t.Fatalf("status = %d, want %d", c.statusCode, http.StatusMovedPermanently)
+	}
+	if want := "https://www.example.com/"; c.headers["Location"] != want {
+		t.Fatalf("Location = %q, want %q", c.headers["Location"], want)
+	}
+}
+
+func TestCanonicalHostPassesThroughOnMatch(t *testing.T) {
+	c := newFakeHttpContext("")
+	c.scheme = "https"
+	c.host = "www.example.com"
+
+	nextCalled := false
+	handler := CanonicalHost("https", "www.example.com", http.StatusMovedPermanently)
+	c.runChain(handler, func(wiston.HttpContext) { nextCalled = true })
+
+	if !nextCalled {
+		t.Fatal("expected the canonical request to reach the next handler")
+	}
+	if c.IsAborted() {
+		t.Fatal("did not expect the chain to be aborted for a canonical request")
+	}
+}
@@ -0,0 +1,164 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"encoding/json"
+	"strings"
+
+	"github.com/smtdfc/wiston"
+)
+
+// Compress wraps a single handler so that responses it writes via Text,
+// JSON, HTML, or Blob are gzip- or deflate-compressed, negotiated from the
+// request's Accept-Encoding header and restricted to the given response
+// content types (matched as prefixes against the response's actual
+// Content-Type; no restriction if types is empty). level follows
+// compress/gzip's level constants.
+//
+// Unlike the rest of this package, Compress wraps a handler directly
+// (`func(wiston.HttpHandler) wiston.HttpHandler`, as gorilla/handlers'
+// CompressHandler does) instead of composing via HttpContext.Next: it
+// needs to intercept the bytes the handler writes, which HttpContext's
+// Next/Abort chain has no hook for. Apply it at the route, not via
+// HttpScope.Use:
+//
+//	scope.Get("/report", middleware.Compress(gzip.DefaultCompression)(reportHandler))
+func Compress(level int, types ...string) func(wiston.HttpHandler) wiston.HttpHandler {
+	return func(next wiston.HttpHandler) wiston.HttpHandler {
+		return func(c wiston.HttpContext) {
+			encoding := negotiateEncoding(c.Header("Accept-Encoding"))
+			if encoding == "" {
+				next(c)
+				return
+			}
+			next(&compressingContext{HttpContext: c, encoding: encoding, level: level, types: types})
+		}
+	}
+}
+
+// negotiateEncoding picks the first encoding wiston supports from an
+// Accept-Encoding header, preferring gzip over deflate.
+func negotiateEncoding(acceptEncoding string) string {
+	seen := make(map[string]bool)
+	for _, enc := range strings.Split(acceptEncoding, ",") {
+		enc = strings.TrimSpace(strings.SplitN(enc, ";", 2)[0])
+		seen[enc] = true
+	}
+
+	switch {
+	case seen["gzip"]:
+		return "gzip"
+	case seen["deflate"]:
+		return "deflate"
+	default:
+		return ""
+	}
+}
+
+// compressingContext decorates an HttpContext, compressing the body of any
+// response written through Text, JSON, HTML, or Blob before handing it to
+// the underlying context's Blob.
+type compressingContext struct {
+	wiston.HttpContext
+	encoding string
+	level    int
+	types    []string
+}
+
+// Text implements HttpContext, compressing data if its content type is in
+// scope.
+func (c *compressingContext) Text(code int, data string) wiston.HttpContext {
+	return c.compress(code, "text/plain; charset=utf-8", []byte(data))
+}
+
+// JSON implements HttpContext, compressing the encoded data if its content
+// type is in scope.
+func (c *compressingContext) JSON(code int, data any) wiston.HttpContext {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return c.HttpContext.JSON(code, data)
+	}
+	return c.compress(code, "application/json", body)
+}
+
+// HTML implements HttpContext, compressing html if its content type is in
+// scope.
+func (c *compressingContext) HTML(code int, html string) wiston.HttpContext {
+	return c.compress(code, "text/html; charset=utf-8", []byte(html))
+}
+
+// Blob implements HttpContext, compressing data if contentType is in
+// scope.
+func (c *compressingContext) Blob(code int, contentType string, data []byte) wiston.HttpContext {
+	return c.compress(code, contentType, data)
+}
+
+// compress encodes data under c.encoding and writes it through the
+// underlying context's Blob with the Content-Encoding header set,
+// falling back to an uncompressed Blob if contentType is out of scope or
+// encoding fails.
+func (c *compressingContext) compress(code int, contentType string, data []byte) wiston.HttpContext {
+	if !c.allowsType(contentType) {
+		return c.HttpContext.Blob(code, contentType, data)
+	}
+
+	encoded, err := c.encode(data)
+	if err != nil {
+		return c.HttpContext.Blob(code, contentType, data)
+	}
+
+	c.HttpContext.SetHeader("Content-Encoding", c.encoding)
+	c.HttpContext.SetHeader("Vary", "Accept-Encoding")
+	return c.HttpContext.Blob(code, contentType, encoded)
+}
+
+// allowsType reports whether contentType is covered by c.types, which
+// matches as prefixes and allows every type when empty.
+func (c *compressingContext) allowsType(contentType string) bool {
+	if len(c.types) == 0 {
+		return true
+	}
+	for _, t := range c.types {
+		if strings.HasPrefix(contentType, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// encode compresses data under c.encoding ("gzip" or "deflate").
+func (c *compressingContext) encode(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	var w interface {
+		Write([]byte) (int, error)
+		Close() error
+	}
+
+	switch c.encoding {
+	case "gzip":
+		gz, err := gzip.NewWriterLevel(&buf, c.level)
+		if err != nil {
+			return nil, err
+		}
+		w = gz
+	case "deflate":
+		fl, err := flate.NewWriter(&buf, c.level)
+		if err != nil {
+			return nil, err
+		}
+		w = fl
+	default:
+		return data, nil
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,190 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/smtdfc/wiston"
+)
+
+// fakeHttpContext is a minimal wiston.HttpContext used to drive middleware
+// without a real gateway. Next simulates a scope's handler chain: it calls
+// the next handler in handlers, if any and the chain hasn't been aborted,
+// so middleware under test can be composed with a downstream handler via
+// runChain the same way HttpScope.Use would compose them in a real app.
+type fakeHttpContext struct {
+	requestHeaders map[string]string
+	headers        map[string]string
+	data           map[string]any
+
+	method string
+	scheme string
+	host   string
+
+	handlers []wiston.HttpHandler
+	index    int
+	aborted  bool
+
+	statusCode      int
+	blobCode        int
+	blobContentType string
+	blobData        []byte
+}
+
+func newFakeHttpContext(acceptEncoding string) *fakeHttpContext {
+	return &fakeHttpContext{
+		requestHeaders: map[string]string{"Accept-Encoding": acceptEncoding},
+		headers:        map[string]string{},
+		data:           map[string]any{},
+		method:         "GET",
+		scheme:         "http",
+		host:           "example.com",
+	}
+}
+
+// runChain runs handlers as a middleware chain, starting the first handler
+// and letting each one's call to c.Next() advance to the next.
+func (c *fakeHttpContext) runChain(handlers ...wiston.HttpHandler) {
+	c.handlers = handlers
+	c.index = -1
+	c.Next()
+}
+
+func (c *fakeHttpContext) Method() string   { return c.method }
+func (c *fakeHttpContext) Path() string     { return "/" }
+func (c *fakeHttpContext) Protocol() string { return "HTTP/1.1" }
+func (c *fakeHttpContext) Scheme() string   { return c.scheme }
+func (c *fakeHttpContext) Host() string     { return c.host }
+
+func (c *fakeHttpContext) Param(string) string               { return "" }
+func (c *fakeHttpContext) Query(string) string               { return "" }
+func (c *fakeHttpContext) QueryDefault(_, def string) string { return def }
+func (c *fakeHttpContext) Header(key string) string          { return c.requestHeaders[key] }
+func (c *fakeHttpContext) Cookie(string) string              { return "" }
+func (c *fakeHttpContext) Body() []byte                      { return nil }
+func (c *fakeHttpContext) FormValue(string) string           { return "" }
+func (c *fakeHttpContext) FormFile(string) ([]byte, error)   { return nil, nil }
+
+func (c *fakeHttpContext) Status(code int) wiston.HttpContext {
+	c.statusCode = code
+	return c
+}
+func (c *fakeHttpContext) SetHeader(key, value string) wiston.HttpContext {
+	c.headers[key] = value
+	return c
+}
+func (c *fakeHttpContext) SetCookie(string, string, ...any) wiston.HttpContext { return c }
+
+func (c *fakeHttpContext) Text(code int, data string) wiston.HttpContext {
+	return c.Blob(code, "text/plain; charset=utf-8", []byte(data))
+}
+func (c *fakeHttpContext) JSON(code int, data any) wiston.HttpContext {
+	body, _ := json.Marshal(data)
+	return c.Blob(code, "application/json", body)
+}
+func (c *fakeHttpContext) HTML(code int, html string) wiston.HttpContext {
+	return c.Blob(code, "text/html; charset=utf-8", []byte(html))
+}
+func (c *fakeHttpContext) Blob(code int, contentType string, data []byte) wiston.HttpContext {
+	c.blobCode = code
+	c.blobContentType = contentType
+	c.blobData = data
+	return c
+}
+func (c *fakeHttpContext) File(int, string) wiston.HttpContext { return c }
+
+func (c *fakeHttpContext) Next() wiston.HttpContext {
+	c.index++
+	if !c.aborted && c.index < len(c.handlers) {
+		c.handlers[c.index](c)
+	}
+	return c
+}
+func (c *fakeHttpContext) Abort() wiston.HttpContext {
+	c.aborted = true
+	return c
+}
+func (c *fakeHttpContext) IsAborted() bool { return c.aborted }
+
+func (c *fakeHttpContext) Set(key string, value any) wiston.HttpContext {
+	c.data[key] = value
+	return c
+}
+func (c *fakeHttpContext) Get(key string) any { return c.data[key] }
+func (c *fakeHttpContext) MustGet(key string) any {
+	v, ok := c.data[key]
+	if !ok {
+		panic("wiston: key not found: " + key)
+	}
+	return v
+}
+
+func TestCompressGzipsJSONBodyWhenAccepted(t *testing.T) {
+	c := newFakeHttpContext("gzip, deflate")
+
+	handler := Compress(gzip.DefaultCompression)(func(ctx wiston.HttpContext) {
+		ctx.JSON(wiston.HttpStatus.OK, map[string]string{"hello": "world"})
+	})
+	handler(c)
+
+	if got := c.headers["Content-Encoding"]; got != "gzip" {
+		t.Fatalf("Content-Encoding = %q, want %q", got, "gzip")
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(c.blobData))
+	if err != nil {
+		t.Fatalf("response body is not valid gzip: %v", err)
+	}
+	decoded, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("failed to decompress response body: %v", err)
+	}
+
+	var got map[string]string
+	if err := json.Unmarshal(decoded, &got); err != nil {
+		t.Fatalf("decompressed body is not the expected JSON: %v", err)
+	}
+	if got["hello"] != "world" {
+		t.Fatalf("decompressed body = %v, want {hello: world}", got)
+	}
+}
+
+func TestCompressPassesThroughWhenClientDoesNotAcceptIt(t *testing.T) {
+	c := newFakeHttpContext("")
+
+	handler := Compress(gzip.DefaultCompression)(func(ctx wiston.HttpContext) {
+		ctx.JSON(wiston.HttpStatus.OK, map[string]string{"hello": "world"})
+	})
+	handler(c)
+
+	if _, ok := c.headers["Content-Encoding"]; ok {
+		t.Fatalf("Content-Encoding should not be set, got %q", c.headers["Content-Encoding"])
+	}
+
+	var got map[string]string
+	if err := json.Unmarshal(c.blobData, &got); err != nil {
+		t.Fatalf("body should be plain JSON, got undecodable bytes: %v", err)
+	}
+	if got["hello"] != "world" {
+		t.Fatalf("body = %v, want {hello: world}", got)
+	}
+}
+
+func TestCompressRestrictsByContentType(t *testing.T) {
+	c := newFakeHttpContext("gzip")
+
+	handler := Compress(gzip.DefaultCompression, "application/json")(func(ctx wiston.HttpContext) {
+		ctx.Text(wiston.HttpStatus.OK, "plain text response")
+	})
+	handler(c)
+
+	if _, ok := c.headers["Content-Encoding"]; ok {
+		t.Fatalf("Content-Encoding should not be set for an out-of-scope type, got %q", c.headers["Content-Encoding"])
+	}
+	if string(c.blobData) != "plain text response" {
+		t.Fatalf("body = %q, want unmodified text", c.blobData)
+	}
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/smtdfc/wiston"
+)
+
+// CORSOptions configures the CORS middleware for a single scope.
+type CORSOptions struct {
+	// AllowOrigins lists the origins allowed to make cross-origin requests.
+	// A single "*" allows any origin.
+	AllowOrigins []string
+	// AllowMethods lists the methods allowed in a preflight request.
+	AllowMethods []string
+	// AllowHeaders lists the request headers allowed in a preflight request.
+	AllowHeaders []string
+	// ExposeHeaders lists the response headers browsers are allowed to read.
+	ExposeHeaders []string
+	// AllowCredentials sets Access-Control-Allow-Credentials.
+	AllowCredentials bool
+	// MaxAge controls how long a preflight response may be cached.
+	MaxAge time.Duration
+}
+
+// CORS returns middleware that applies the given CORSOptions's allow-list
+// to every request in scope, answering preflight OPTIONS requests directly
+// and aborting the handler chain.
+func CORS(opts CORSOptions) wiston.HttpHandler {
+	allowAll := len(opts.AllowOrigins) == 1 && opts.AllowOrigins[0] == "*"
+
+	return func(c wiston.HttpContext) {
+		origin := c.Header("Origin")
+		if origin == "" || (!allowAll && !contains(opts.AllowOrigins, origin)) {
+			c.Next()
+			return
+		}
+
+		if allowAll {
+			c.SetHeader("Access-Control-Allow-Origin", "*")
+		} else {
+			c.SetHeader("Access-Control-Allow-Origin", origin)
+			c.SetHeader("Vary", "Origin")
+		}
+
+		if opts.AllowCredentials {
+			c.SetHeader("Access-Control-Allow-Credentials", "true")
+		}
+		if len(opts.ExposeHeaders) > 0 {
+			c.SetHeader("Access-Control-Expose-Headers", strings.Join(opts.ExposeHeaders, ", "))
+		}
+
+		if c.Method() != "OPTIONS" {
+			c.Next()
+			return
+		}
+
+		if len(opts.AllowMethods) > 0 {
+			c.SetHeader("Access-Control-Allow-Methods", strings.Join(opts.AllowMethods, ", "))
+		}
+		if len(opts.AllowHeaders) > 0 {
+			c.SetHeader("Access-Control-Allow-Headers", strings.Join(opts.AllowHeaders, ", "))
+		}
+		if opts.MaxAge > 0 {
+			c.SetHeader("Access-Control-Max-Age", strconv.Itoa(int(opts.MaxAge.Seconds())))
+		}
+
+		c.Status(wiston.HttpStatus.NoContent)
+		c.Abort()
+	}
+}
+
+func contains(list []string, value string) bool {
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
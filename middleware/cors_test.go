@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"testing"
+	"time"
+
+	"github.com/smtdfc/wiston"
+)
+
+func TestCORSPreflightRespondsWithAllowHeadersAndAborts(t *testing.T) {
+	c := newFakeHttpContext("")
+	c.method = "OPTIONS"
+	c.requestHeaders["Origin"] = "https://example.com"
+
+	nextCalled := false
+	handler := CORS(CORSOptions{
+		AllowOrigins: []string{"https://example.com"},
+		AllowMethods: []string{"GET", "POST"},
+		AllowHeaders: []string{"Content-Type"},
+		MaxAge:       10 * time.Minute,
+	})
+	c.runChain(handler, func(wiston.HttpContext) { nextCalled = true })
+
+	if nextCalled {
+		t.Fatal("a preflight request should not reach the next handler")
+	}
+	if !c.IsAborted() {
+		t.Fatal("expected the preflight response to abort the chain")
+	}
+	if c.statusCode != wiston.HttpStatus.NoContent {
+		t.Fatalf("status = %d, want %d", c.statusCode, wiston.HttpStatus.NoContent)
+	}
+	if got := c.headers["Access-Control-Allow-Origin"]; got != "https://example.com" {
+		t.Fatalf("Access-Control-Allow-Origin = %q, want %q", got, "https://example.com")
+	}
+	if got := c.headers["Access-Control-Allow-Methods"]; got != "GET, POST" {
+		t.Fatalf("Access-Control-Allow-Methods = %q, want %q", got, "GET, POST")
+	}
+	if got := c.headers["Access-Control-Allow-Headers"]; got != "Content-Type" {
+		t.Fatalf("Access-Control-Allow-Headers = %q, want %q", got, "Content-Type")
+	}
+	if got := c.headers["Access-Control-Max-Age"]; got != "600" {
+		t.Fatalf("Access-Control-Max-Age = %q, want %q", got, "600")
+	}
+}
+
+func TestCORSSimpleRequestSetsAllowOriginAndContinues(t *testing.T) {
+	c := newFakeHttpContext("")
+	c.requestHeaders["Origin"] = "https://example.com"
+
+	nextCalled := false
+	handler := CORS(CORSOptions{AllowOrigins: []string{"*"}})
+	c.runChain(handler, func(wiston.HttpContext) { nextCalled = true })
+
+	if !nextCalled {
+		t.Fatal("expected the next handler to run for a simple request")
+	}
+	if c.IsAborted() {
+		t.Fatal("did not expect the chain to be aborted")
+	}
+	if got := c.headers["Access-Control-Allow-Origin"]; got != "*" {
+		t.Fatalf("Access-Control-Allow-Origin = %q, want %q", got, "*")
+	}
+}
+
+func TestCORSIgnoresDisallowedOrigin(t *testing.T) {
+	c := newFakeHttpContext("")
+	c.requestHeaders["Origin"] = "https://evil.example"
+
+	nextCalled := false
+	handler := CORS(CORSOptions{AllowOrigins: []string{"https://example.com"}})
+	c.runChain(handler, func(wiston.HttpContext) { nextCalled = true })
+
+	if !nextCalled {
+		t.Fatal("expected the next handler to still run for a disallowed origin")
+	}
+	if _, ok := c.headers["Access-Control-Allow-Origin"]; ok {
+		t.Fatal("did not expect Access-Control-Allow-Origin to be set for a disallowed origin")
+	}
+}
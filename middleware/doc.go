@@ -0,0 +1,8 @@
+// Package middleware provides composable HTTP middleware for wiston
+// applications, analogous to gorilla/handlers: panic recovery, response
+// compression, CORS, canonical host redirects, and request id stamping.
+// Every middleware except Compress is a plain wiston.HttpHandler and
+// composes with HttpContext's Next/Abort/IsAborted flow control, so it can
+// be applied via HttpScope.Use or HttpGateway.Use like any other handler.
+// Compress instead wraps a single handler and is applied at the route.
+package middleware
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"github.com/smtdfc/wiston"
+)
+
+// Recover returns middleware that recovers from panics in later handlers,
+// logs them via logger, and responds with 500 Internal Server Error
+// instead of letting the panic crash the gateway.
+func Recover(logger wiston.Logger) wiston.HttpHandler {
+	return func(c wiston.HttpContext) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Error("panic recovered",
+					wiston.String("path", c.Path()),
+					wiston.Any("panic", r),
+				)
+				c.Text(wiston.HttpStatus.InternalServerError, "Internal Server Error")
+				c.Abort()
+			}
+		}()
+		c.Next()
+	}
+}
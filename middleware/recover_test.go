@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/smtdfc/wiston"
+)
+
+// testSink collects every Entry written to it, for assertions.
+type testSink struct {
+	entries []wiston.Entry
+}
+
+func (s *testSink) Write(entry wiston.Entry) {
+	s.entries = append(s.entries, entry)
+}
+
+func TestRecoverConvertsPanicIntoInternalServerError(t *testing.T) {
+	sink := &testSink{}
+	logger := wiston.NewLoggerWithSinks(sink)
+
+	c := newFakeHttpContext("")
+	c.runChain(Recover(logger), func(wiston.HttpContext) {
+		panic("boom")
+	})
+
+	if c.blobCode != wiston.HttpStatus.InternalServerError {
+		t.Fatalf("status = %d, want %d", c.blobCode, wiston.HttpStatus.InternalServerError)
+	}
+	if string(c.blobData) != "Internal Server Error" {
+		t.Fatalf("body = %q, want %q", c.blobData, "Internal Server Error")
+	}
+	if !c.IsAborted() {
+		t.Fatal("expected the chain to be aborted after recovering from a panic")
+	}
+	if len(sink.entries) != 1 {
+		t.Fatalf("got %d log entries, want 1", len(sink.entries))
+	}
+}
+
+func TestRecoverLeavesNonPanickingHandlersUntouched(t *testing.T) {
+	sink := &testSink{}
+	logger := wiston.NewLoggerWithSinks(sink)
+
+	called := false
+	c := newFakeHttpContext("")
+	c.runChain(Recover(logger), func(wiston.HttpContext) {
+		called = true
+	})
+
+	if !called {
+		t.Fatal("expected the next handler to run")
+	}
+	if c.IsAborted() {
+		t.Fatal("did not expect the chain to be aborted")
+	}
+	if len(sink.entries) != 0 {
+		t.Fatalf("got %d log entries, want 0", len(sink.entries))
+	}
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/smtdfc/wiston"
+)
+
+// RequestIDContextKey is the HttpContext key RequestID stores the
+// request's id under.
+const RequestIDContextKey = "wiston:middleware:request_id"
+
+// RequestIDHeader is the header RequestID reads an existing id from and
+// echoes the final id back on.
+const RequestIDHeader = "X-Request-Id"
+
+// RequestID returns middleware that stamps each request with a unique id,
+// reusing one supplied by the client via RequestIDHeader if present. The id
+// is available to later handlers via HttpContext.Get(RequestIDContextKey)
+// and is set on the response via RequestIDHeader.
+func RequestID() wiston.HttpHandler {
+	return func(c wiston.HttpContext) {
+		id := c.Header(RequestIDHeader)
+		if id == "" {
+			id = generateRequestID()
+		}
+
+		c.Set(RequestIDContextKey, id)
+		c.SetHeader(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// generateRequestID returns a random 32-character hex string, or an empty
+// string if the system's secure random source is unavailable.
+func generateRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/smtdfc/wiston"
+)
+
+func TestRequestIDGeneratesAndStampsAnID(t *testing.T) {
+	c := newFakeHttpContext("")
+
+	nextCalled := false
+	c.runChain(RequestID(), func(wiston.HttpContext) { nextCalled = true })
+
+	if !nextCalled {
+		t.Fatal("expected the next handler to run")
+	}
+	id, _ := c.Get(RequestIDContextKey).(string)
+	if id == "" {
+		t.Fatal("expected a generated request id to be stored in context")
+	}
+	if got := c.headers[RequestIDHeader]; got != id {
+		t.Fatalf("response header %s = %q, want it to match the stored id %q", RequestIDHeader, got, id)
+	}
+}
+
+func TestRequestIDReusesClientSuppliedID(t *testing.T) {
+	c := newFakeHttpContext("")
+	c.requestHeaders[RequestIDHeader] = "client-supplied-id"
+
+	c.runChain(RequestID(), func(wiston.HttpContext) {})
+
+	if got := c.Get(RequestIDContextKey); got != "client-supplied-id" {
+		t.Fatalf("context id = %v, want %q", got, "client-supplied-id")
+	}
+	if got := c.headers[RequestIDHeader]; got != "client-supplied-id" {
+		t.Fatalf("response header = %q, want %q", got, "client-supplied-id")
+	}
+}
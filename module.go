@@ -5,6 +5,9 @@ package wiston
 type Module struct {
 	// Name is the identifier of the module.
 	Name string
+
+	onStartCallbacks []func() error
+	onStopCallbacks  []func() error
 }
 
 // NewModule creates a new Module with the given name.
@@ -13,3 +16,40 @@ func NewModule(name string) *Module {
 		Name: name,
 	}
 }
+
+// OnStart registers a callback to be invoked when the application starts
+// this module. Callbacks run in registration order; the first error
+// returned aborts startup.
+func (m *Module) OnStart(cb func() error) {
+	m.onStartCallbacks = append(m.onStartCallbacks, cb)
+}
+
+// OnStop registers a callback to be invoked when the application shuts
+// this module down. Callbacks run in registration order; an error does not
+// stop later callbacks from running, and the first one is reported.
+func (m *Module) OnStop(cb func() error) {
+	m.onStopCallbacks = append(m.onStopCallbacks, cb)
+}
+
+// triggerStart runs all registered start callbacks in order, returning the
+// first error encountered.
+func (m *Module) triggerStart() error {
+	for _, cb := range m.onStartCallbacks {
+		if err := cb(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// triggerStop runs all registered stop callbacks in order, returning the
+// first error encountered.
+func (m *Module) triggerStop() error {
+	var firstErr error
+	for _, cb := range m.onStopCallbacks {
+		if err := cb(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
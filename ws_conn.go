@@ -0,0 +1,125 @@
+package wiston
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// pingInterval is how often wsConn sends a ping frame to keep idle, NATed
+// WebSocket connections alive and to detect dead peers.
+const pingInterval = 30 * time.Second
+
+// pongWait is how long wsConn waits for a pong before treating the
+// connection as dead; it must be longer than pingInterval.
+const pongWait = 2 * pingInterval
+
+// wsConn wraps a single *websocket.Conn so that concurrent goroutines —
+// HTTP handlers, the EventBus, room broadcasts — can all write to it
+// safely. gorilla/websocket forbids concurrent writes to the same
+// connection, so every WS gateway implementation must route outbound
+// frames through a wsConn instead of calling the underlying connection
+// directly.
+type wsConn struct {
+	mu   sync.Mutex
+	conn *websocket.Conn
+
+	writeTimeout time.Duration
+
+	closeOnce sync.Once
+	quit      chan struct{}
+}
+
+// newWsConn wraps conn, installs a pong handler that extends the read
+// deadline on every pong, and starts the ping keepalive loop.
+func newWsConn(conn *websocket.Conn) *wsConn {
+	c := &wsConn{
+		conn: conn,
+		quit: make(chan struct{}),
+	}
+
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	// Arm the read deadline before the first pong ever arrives; otherwise a
+	// peer that goes dark right after connecting is never detected, since
+	// there would be no deadline at all until its first pong.
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+
+	go c.keepalive()
+	return c
+}
+
+// WriteMessage sends a single WebSocket frame, serialized against any other
+// writer on this connection.
+func (c *wsConn) WriteMessage(messageType int, data []byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.applyDeadline(); err != nil {
+		return err
+	}
+	return c.conn.WriteMessage(messageType, data)
+}
+
+// WriteJSON encodes v as JSON and sends it as a single text frame,
+// serialized against any other writer on this connection.
+func (c *wsConn) WriteJSON(v any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.applyDeadline(); err != nil {
+		return err
+	}
+	return c.conn.WriteJSON(v)
+}
+
+// applyDeadline sets the connection's write deadline from writeTimeout,
+// clearing it when writeTimeout is zero. Callers must hold mu.
+func (c *wsConn) applyDeadline() error {
+	if c.writeTimeout <= 0 {
+		return c.conn.SetWriteDeadline(time.Time{})
+	}
+	return c.conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+}
+
+// SetWriteDeadline sets how long a future write on this connection may
+// take before it fails. A zero duration disables the deadline.
+func (c *wsConn) SetWriteDeadline(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.writeTimeout = d
+}
+
+// keepalive periodically sends ping frames so idle NATed clients aren't
+// silently dropped by intermediate routers, and so a dead peer is detected
+// once its pongs stop arriving.
+func (c *wsConn) keepalive() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.mu.Lock()
+			err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(pingInterval))
+			c.mu.Unlock()
+			if err != nil {
+				return
+			}
+		case <-c.quit:
+			return
+		}
+	}
+}
+
+// Close stops the keepalive loop and closes the underlying connection.
+func (c *wsConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.quit)
+	})
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn.Close()
+}
@@ -0,0 +1,134 @@
+package wiston
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+var testUpgrader = websocket.Upgrader{}
+
+// newTestWsConnPair starts a real WebSocket handshake over httptest so
+// tests exercise wsConn against an actual *websocket.Conn rather than a
+// fake, returning the server-side wsConn, the client-side connection, and a
+// cleanup func.
+func newTestWsConnPair(t *testing.T) (*wsConn, *websocket.Conn, func()) {
+	t.Helper()
+
+	serverConnCh := make(chan *websocket.Conn, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := testUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		serverConnCh <- c
+	}))
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http")
+	clientConn, _, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	if err != nil {
+		srv.Close()
+		t.Fatalf("dial: %v", err)
+	}
+
+	serverConn := <-serverConnCh
+	wc := newWsConn(serverConn)
+
+	cleanup := func() {
+		wc.Close()
+		clientConn.Close()
+		srv.Close()
+	}
+	return wc, clientConn, cleanup
+}
+
+// TestWsConnConcurrentWritesDoNotRace drives WriteMessage and WriteJSON from
+// many goroutines at once; run with -race to confirm wsConn's mutex
+// actually serializes writes to the underlying *websocket.Conn, which
+// forbids concurrent writers.
+func TestWsConnConcurrentWritesDoNotRace(t *testing.T) {
+	wc, clientConn, cleanup := newTestWsConnPair(t)
+	defer cleanup()
+
+	go func() {
+		for {
+			if _, _, err := clientConn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	const n = 20
+	var wg sync.WaitGroup
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			var err error
+			if i%2 == 0 {
+				err = wc.WriteMessage(websocket.TextMessage, []byte("hello"))
+			} else {
+				err = wc.WriteJSON(map[string]int{"i": i})
+			}
+			if err != nil {
+				t.Errorf("concurrent write %d failed: %v", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+// TestWsConnPongHandlerExtendsReadDeadline confirms the pong handler
+// installed by newWsConn pushes the read deadline forward, rather than the
+// connection being timed out by a deadline armed before the pong arrived.
+func TestWsConnPongHandlerExtendsReadDeadline(t *testing.T) {
+	wc, clientConn, cleanup := newTestWsConnPair(t)
+	defer cleanup()
+
+	// Replace the deadline newWsConn armed with one short enough to observe
+	// being pushed out again within this test's lifetime.
+	if err := wc.conn.SetReadDeadline(time.Now().Add(50 * time.Millisecond)); err != nil {
+		t.Fatalf("SetReadDeadline: %v", err)
+	}
+
+	msgs := make(chan []byte, 1)
+	errs := make(chan error, 1)
+	go func() {
+		for {
+			_, data, err := wc.conn.ReadMessage()
+			if err != nil {
+				errs <- err
+				return
+			}
+			msgs <- data
+		}
+	}()
+
+	if err := clientConn.WriteMessage(websocket.PongMessage, nil); err != nil {
+		t.Fatalf("client write pong: %v", err)
+	}
+
+	// Let the server's read loop process the pong (and extend the
+	// deadline) before sending real data past the original short deadline.
+	time.Sleep(100 * time.Millisecond)
+	if err := clientConn.WriteMessage(websocket.TextMessage, []byte("after pong")); err != nil {
+		t.Fatalf("client write message: %v", err)
+	}
+
+	select {
+	case data := <-msgs:
+		if string(data) != "after pong" {
+			t.Fatalf("got message %q, want %q", data, "after pong")
+		}
+	case err := <-errs:
+		t.Fatalf("read failed, pong handler likely did not extend the deadline: %v", err)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the post-pong message")
+	}
+}